@@ -0,0 +1,44 @@
+// Package chain defines the interface a wallet uses to talk to the
+// Bitcoin network, so the rest of the codebase doesn't care whether UTXOs,
+// fee rates, and broadcasts come from an Esplora-style REST API or an
+// Electrum server.
+package chain
+
+import "github.com/btcsuite/btcd/wire"
+
+// UTXO represents an unspent transaction output.
+type UTXO struct {
+	OutPoint wire.OutPoint
+	Value    int64
+	PkScript []byte
+}
+
+// TxStatus describes a transaction's confirmation state.
+type TxStatus struct {
+	Confirmed   bool
+	BlockHeight int32
+}
+
+// Backend is the set of network operations a wallet needs from a Bitcoin
+// full node, indexer, or Electrum server. Implementations live in
+// subpackages (esplora, electrumx) so the wallet package never imports a
+// specific backend directly.
+type Backend interface {
+	// UTXOs returns every unspent output paying to address, with PkScript
+	// already populated.
+	UTXOs(address string) ([]UTXO, error)
+	// ScriptPubKey returns the output script of transaction txid's output
+	// vout.
+	ScriptPubKey(txid string, vout int) ([]byte, error)
+	// Broadcast submits a raw serialized transaction to the network.
+	Broadcast(rawTx []byte) error
+	// FeeRate estimates the fee rate, in satoshis per vbyte, needed for a
+	// transaction to confirm within target blocks.
+	FeeRate(target int) (float64, error)
+	// TxStatus reports whether txid has confirmed, and at what height.
+	TxStatus(txid string) (TxStatus, error)
+	// AddressTxCount returns the number of transactions (confirmed or in
+	// the mempool) touching address, used to detect whether an address has
+	// ever been used.
+	AddressTxCount(address string) (int, error)
+}
@@ -0,0 +1,390 @@
+// Package electrumx implements chain.Backend against an Electrum server's
+// JSON-RPC protocol, spoken over a single persistent TCP+TLS connection.
+package electrumx
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain"
+)
+
+// Backend speaks Electrum's JSON-RPC protocol over a single persistent
+// TCP+TLS connection, multiplexing concurrent requests by id and
+// delivering scripthash subscription notifications to registered
+// listeners.
+type Backend struct {
+	params *chaincfg.Params
+	conn   net.Conn
+
+	mu       sync.Mutex
+	nextID   int
+	pending  map[int]chan rpcResponse
+	subs     map[string]chan<- struct{} // scripthash -> mempool-change notification channel
+	closed   bool
+	closeErr error
+
+	writeMu sync.Mutex
+}
+
+// rpcRequest is an Electrum JSON-RPC request.
+type rpcRequest struct {
+	ID     int           `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// rpcResponse is an Electrum JSON-RPC response or notification. Responses
+// carry an ID matching a pending request; notifications carry a Method and
+// Params instead.
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// Dial connects to an Electrum server at addr over TLS and starts the
+// background read loop that demultiplexes responses and notifications.
+func Dial(addr string, tlsConfig *tls.Config, params *chaincfg.Params) (*Backend, error) {
+	conn, err := tls.Dial("tcp", addr, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to electrum server: %v", err)
+	}
+
+	b := &Backend{
+		params:  params,
+		conn:    conn,
+		pending: make(map[int]chan rpcResponse),
+		subs:    make(map[string]chan<- struct{}),
+	}
+	go b.readLoop()
+
+	return b, nil
+}
+
+// readLoop reads newline-delimited JSON-RPC frames from the connection for
+// the lifetime of the Backend, routing each to the pending call it answers
+// or to a scripthash subscriber if it's a notification.
+func (b *Backend) readLoop() {
+	scanner := bufio.NewScanner(b.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var resp rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			continue
+		}
+
+		if resp.Method == "blockchain.scripthash.subscribe" {
+			b.dispatchNotification(resp.Params)
+			continue
+		}
+
+		b.mu.Lock()
+		ch, ok := b.pending[resp.ID]
+		if ok {
+			delete(b.pending, resp.ID)
+		}
+		b.mu.Unlock()
+
+		if ok {
+			ch <- resp
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	b.fail(fmt.Errorf("electrum connection closed: %v", err))
+}
+
+// fail marks the connection dead and delivers err to every call currently
+// blocked in call, so a dropped connection surfaces as an error instead of
+// hanging forever. Any call made afterward fails immediately.
+func (b *Backend) fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.closeErr = err
+
+	for id, ch := range b.pending {
+		ch <- rpcResponse{Error: &rpcError{Message: err.Error()}}
+		delete(b.pending, id)
+	}
+}
+
+// dispatchNotification notifies the subscriber registered for the
+// scripthash named in a blockchain.scripthash.subscribe notification.
+func (b *Backend) dispatchNotification(params json.RawMessage) {
+	var args []string
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	notify, ok := b.subs[args[0]]
+	b.mu.Unlock()
+
+	if ok {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// call sends an Electrum RPC and blocks for its response.
+func (b *Backend) call(method string, params ...interface{}) (json.RawMessage, error) {
+	b.mu.Lock()
+	if b.closed {
+		err := b.closeErr
+		b.mu.Unlock()
+		return nil, fmt.Errorf("electrum connection is closed: %v", err)
+	}
+	b.nextID++
+	id := b.nextID
+	respCh := make(chan rpcResponse, 1)
+	b.pending[id] = respCh
+	b.mu.Unlock()
+
+	payload, err := json.Marshal(rpcRequest{ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	b.writeMu.Lock()
+	_, err = b.conn.Write(append(payload, '\n'))
+	b.writeMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	resp := <-respCh
+	if resp.Error != nil {
+		return nil, fmt.Errorf("electrum server error: %s", resp.Error.Message)
+	}
+
+	return resp.Result, nil
+}
+
+// SubscribeAddress subscribes to mempool/chain-tip changes for address,
+// delivering a notification on notify each time its scripthash's status
+// changes. The channel should be buffered or drained promptly; stale
+// notifications are dropped rather than blocking the read loop.
+func (b *Backend) SubscribeAddress(address string, notify chan<- struct{}) error {
+	sh, err := b.scripthashFor(address)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.subs[sh] = notify
+	b.mu.Unlock()
+
+	_, err = b.call("blockchain.scripthash.subscribe", sh)
+	return err
+}
+
+// scripthashFor computes the Electrum scripthash for address: the
+// reversed sha256 of its output script, hex-encoded.
+func (b *Backend) scripthashFor(address string) (string, error) {
+	addr, err := btcutil.DecodeAddress(address, b.params)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode address: %v", err)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to build output script: %v", err)
+	}
+
+	return scripthash(pkScript), nil
+}
+
+// scripthash implements the Electrum scripthash convention: sha256(script)
+// with the digest byte-reversed, hex-encoded.
+func scripthash(pkScript []byte) string {
+	digest := sha256.Sum256(pkScript)
+	for i, j := 0, len(digest)-1; i < j; i, j = i+1, j-1 {
+		digest[i], digest[j] = digest[j], digest[i]
+	}
+	return hex.EncodeToString(digest[:])
+}
+
+// electrumUTXO matches an entry returned by
+// blockchain.scripthash.listunspent.
+type electrumUTXO struct {
+	TxHash string `json:"tx_hash"`
+	TxPos  uint32 `json:"tx_pos"`
+	Height int32  `json:"height"`
+	Value  int64  `json:"value"`
+}
+
+// UTXOs implements chain.Backend.
+func (b *Backend) UTXOs(address string) ([]chain.UTXO, error) {
+	sh, err := b.scripthashFor(address)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := b.call("blockchain.scripthash.listunspent", sh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unspent outputs: %v", err)
+	}
+
+	var entries []electrumUTXO
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode unspent outputs: %v", err)
+	}
+
+	utxos := make([]chain.UTXO, len(entries))
+	for i, e := range entries {
+		hash, err := chainhash.NewHashFromStr(e.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse txid: %v", err)
+		}
+
+		pkScript, err := b.ScriptPubKey(e.TxHash, int(e.TxPos))
+		if err != nil {
+			return nil, err
+		}
+
+		utxos[i] = chain.UTXO{
+			OutPoint: wire.OutPoint{Hash: *hash, Index: e.TxPos},
+			Value:    e.Value,
+			PkScript: pkScript,
+		}
+	}
+
+	return utxos, nil
+}
+
+// ScriptPubKey implements chain.Backend by fetching and deserializing the
+// raw transaction and reading the requested output's script.
+func (b *Backend) ScriptPubKey(txid string, vout int) ([]byte, error) {
+	tx, err := b.fetchTx(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	if vout < 0 || vout >= len(tx.TxOut) {
+		return nil, fmt.Errorf("vout index out of range")
+	}
+
+	return tx.TxOut[vout].PkScript, nil
+}
+
+func (b *Backend) fetchTx(txid string) (*wire.MsgTx, error) {
+	raw, err := b.call("blockchain.transaction.get", txid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %v", err)
+	}
+
+	var rawHex string
+	if err := json.Unmarshal(raw, &rawHex); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction response: %v", err)
+	}
+
+	txBytes, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction hex: %v", err)
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %v", err)
+	}
+
+	return &tx, nil
+}
+
+// Broadcast implements chain.Backend.
+func (b *Backend) Broadcast(rawTx []byte) error {
+	_, err := b.call("blockchain.transaction.broadcast", hex.EncodeToString(rawTx))
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %v", err)
+	}
+	return nil
+}
+
+// FeeRate implements chain.Backend. Electrum reports fees in BTC per
+// kilobyte; this converts to the satoshis-per-vbyte convention used
+// elsewhere in this codebase.
+func (b *Backend) FeeRate(target int) (float64, error) {
+	raw, err := b.call("blockchain.estimatefee", target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate fee: %v", err)
+	}
+
+	var btcPerKB float64
+	if err := json.Unmarshal(raw, &btcPerKB); err != nil {
+		return 0, fmt.Errorf("failed to decode fee estimate: %v", err)
+	}
+
+	return btcPerKB * 1e8 / 1000, nil
+}
+
+// TxStatus implements chain.Backend. It reports only whether txid has at
+// least one confirmation; BlockHeight is always left zero.
+func (b *Backend) TxStatus(txid string) (chain.TxStatus, error) {
+	raw, err := b.call("blockchain.transaction.get", txid, true)
+	if err != nil {
+		return chain.TxStatus{}, fmt.Errorf("failed to fetch transaction status: %v", err)
+	}
+
+	var verbose struct {
+		Confirmations int `json:"confirmations"`
+	}
+	if err := json.Unmarshal(raw, &verbose); err != nil {
+		return chain.TxStatus{}, fmt.Errorf("failed to decode transaction status: %v", err)
+	}
+
+	return chain.TxStatus{Confirmed: verbose.Confirmations > 0}, nil
+}
+
+// AddressTxCount implements chain.Backend using the scripthash's full
+// history, Electrum's equivalent of Esplora's /address/{address}/txs.
+func (b *Backend) AddressTxCount(address string) (int, error) {
+	sh, err := b.scripthashFor(address)
+	if err != nil {
+		return 0, err
+	}
+
+	raw, err := b.call("blockchain.scripthash.get_history", sh)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch address history: %v", err)
+	}
+
+	var history []json.RawMessage
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return 0, fmt.Errorf("failed to decode address history: %v", err)
+	}
+
+	return len(history), nil
+}
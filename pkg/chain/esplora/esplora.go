@@ -0,0 +1,208 @@
+// Package esplora implements chain.Backend against a Blockstream-style
+// Esplora REST API.
+package esplora
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain"
+)
+
+// DefaultTestnetURL is Blockstream's public testnet Esplora instance.
+const DefaultTestnetURL = "https://blockstream.info/testnet/api"
+
+// Backend talks to an Esplora instance at BaseURL.
+type Backend struct {
+	BaseURL    string
+	httpClient *http.Client
+}
+
+// New returns a Backend for the Esplora instance at baseURL.
+func New(baseURL string) *Backend {
+	return &Backend{BaseURL: baseURL, httpClient: http.DefaultClient}
+}
+
+// auxUTXO matches the shape of Esplora's /address/{address}/utxo entries.
+type auxUTXO struct {
+	TxID  string `json:"txid"`
+	Vout  uint32 `json:"vout"`
+	Value int64  `json:"value"`
+}
+
+// txVout is a transaction output as returned by Esplora's /tx/{txid}.
+type txVout struct {
+	ScriptPubKey string `json:"scriptpubkey"`
+	Value        int64  `json:"value"`
+}
+
+// txInfo is the subset of Esplora's /tx/{txid} response this backend uses.
+type txInfo struct {
+	Txid   string   `json:"txid"`
+	Vout   []txVout `json:"vout"`
+	Status struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int32 `json:"block_height"`
+	} `json:"status"`
+}
+
+// UTXOs implements chain.Backend.
+func (b *Backend) UTXOs(address string) ([]chain.UTXO, error) {
+	resp, err := b.httpClient.Get(fmt.Sprintf("%s/address/%s/utxo", b.BaseURL, address))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch UTXOs: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var aux []auxUTXO
+	if err := json.NewDecoder(resp.Body).Decode(&aux); err != nil {
+		return nil, fmt.Errorf("failed to decode UTXOs: %v", err)
+	}
+
+	utxos := make([]chain.UTXO, len(aux))
+	for i, a := range aux {
+		hash, err := chainhash.NewHashFromStr(a.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse txid: %v", err)
+		}
+
+		pkScript, err := b.ScriptPubKey(a.TxID, int(a.Vout))
+		if err != nil {
+			return nil, err
+		}
+
+		utxos[i] = chain.UTXO{
+			OutPoint: wire.OutPoint{Hash: *hash, Index: a.Vout},
+			Value:    a.Value,
+			PkScript: pkScript,
+		}
+	}
+
+	return utxos, nil
+}
+
+// ScriptPubKey implements chain.Backend.
+func (b *Backend) ScriptPubKey(txid string, vout int) ([]byte, error) {
+	tx, err := b.fetchTx(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	if vout < 0 || vout >= len(tx.Vout) {
+		return nil, errors.New("vout index out of range")
+	}
+
+	spk, err := hex.DecodeString(tx.Vout[vout].ScriptPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode script pub key hex: %v", err)
+	}
+
+	return spk, nil
+}
+
+// Broadcast implements chain.Backend.
+func (b *Backend) Broadcast(rawTx []byte) error {
+	txHexStr := hex.EncodeToString(rawTx)
+	resp, err := b.httpClient.Post(fmt.Sprintf("%s/tx", b.BaseURL), "text/plain", bytes.NewBufferString(txHexStr))
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read broadcast response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("broadcast failed: %s: %s", resp.Status, string(respBody))
+	}
+
+	log.Printf("Transaction broadcast: %s, Response Body: %s", resp.Status, string(respBody))
+	return nil
+}
+
+// FeeRate implements chain.Backend.
+func (b *Backend) FeeRate(target int) (float64, error) {
+	resp, err := b.httpClient.Get(fmt.Sprintf("%s/fee-estimates", b.BaseURL))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch fee rate: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The API returns floating point sat/vB values keyed by confirmation
+	// target.
+	var feeEstimates map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&feeEstimates); err != nil {
+		return 0, fmt.Errorf("failed to decode fee estimates: %v", err)
+	}
+
+	rate, ok := feeEstimates[fmt.Sprintf("%d", target)]
+	if !ok {
+		return 0, fmt.Errorf("fee estimate for target %d not found", target)
+	}
+
+	return rate, nil
+}
+
+// TxStatus implements chain.Backend.
+func (b *Backend) TxStatus(txid string) (chain.TxStatus, error) {
+	tx, err := b.fetchTx(txid)
+	if err != nil {
+		return chain.TxStatus{}, err
+	}
+
+	return chain.TxStatus{
+		Confirmed:   tx.Status.Confirmed,
+		BlockHeight: tx.Status.BlockHeight,
+	}, nil
+}
+
+// AddressTxCount implements chain.Backend.
+func (b *Backend) AddressTxCount(address string) (int, error) {
+	resp, err := b.httpClient.Get(fmt.Sprintf("%s/address/%s/txs", b.BaseURL, address))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch address txs: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var txs []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&txs); err != nil {
+		return 0, fmt.Errorf("failed to decode address txs: %v", err)
+	}
+
+	return len(txs), nil
+}
+
+func (b *Backend) fetchTx(txid string) (*txInfo, error) {
+	resp, err := b.httpClient.Get(fmt.Sprintf("%s/tx/%s", b.BaseURL, txid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction info: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	var tx txInfo
+	if err := json.Unmarshal(body, &tx); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	return &tx, nil
+}
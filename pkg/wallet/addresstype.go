@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// AddressType selects which derivation path and output script a wallet
+// uses, so the same Wallet/Signer machinery can drive native SegWit,
+// nested SegWit, or Taproot outputs.
+type AddressType int
+
+const (
+	// NativeSegWit is a BIP-84 P2WPKH wallet, m/84'/0'/0'/0/i.
+	NativeSegWit AddressType = iota
+	// NestedSegWit is a BIP-49 P2SH-wrapped P2WPKH wallet, m/49'/0'/0'/0/i.
+	NestedSegWit
+	// Taproot is a BIP-86 P2TR key-path-spend wallet, m/86'/0'/0'/0/i.
+	Taproot
+)
+
+// purpose returns the BIP-43 purpose field for the address type's
+// derivation path (the hardened first path element).
+func (t AddressType) purpose() (uint32, error) {
+	switch t {
+	case NativeSegWit:
+		return 84, nil
+	case NestedSegWit:
+		return 49, nil
+	case Taproot:
+		return 86, nil
+	default:
+		return 0, fmt.Errorf("unknown address type: %d", t)
+	}
+}
+
+// derivationPath returns the BIP-44-style path m/purpose'/0'/0'/chain/index
+// for this address type. chain is 0 for the external (receive) chain and 1
+// for the internal (change) chain.
+func (t AddressType) derivationPath(chain, index uint32) ([]uint32, error) {
+	purpose, err := t.purpose()
+	if err != nil {
+		return nil, err
+	}
+	return []uint32{
+		HardenedOffset + purpose, // Purpose
+		HardenedOffset + 0,       // Coin (Bitcoin Mainnet)
+		HardenedOffset + 0,       // Account 0
+		chain,                    // External (0) or internal/change (1) chain
+		index,                    // Address index
+	}, nil
+}
+
+// accountPath returns the BIP-44-style account-level path m/purpose'/0'/0'
+// for this address type, i.e. derivationPath without the chain/index
+// components.
+func (t AddressType) accountPath() ([]uint32, error) {
+	purpose, err := t.purpose()
+	if err != nil {
+		return nil, err
+	}
+	return []uint32{
+		HardenedOffset + purpose,
+		HardenedOffset + 0,
+		HardenedOffset + 0,
+	}, nil
+}
+
+// addressForPubKey derives the address for pubKey under this address type.
+func (t AddressType) addressForPubKey(pubKey *btcec.PublicKey) (string, error) {
+	switch t {
+	case NativeSegWit:
+		pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, &CHAIN_CONFIG)
+		if err != nil {
+			return "", err
+		}
+		return addr.String(), nil
+
+	case NestedSegWit:
+		redeemScript, err := p2wpkhRedeemScript(pubKey)
+		if err != nil {
+			return "", err
+		}
+		addr, err := btcutil.NewAddressScriptHash(redeemScript, &CHAIN_CONFIG)
+		if err != nil {
+			return "", err
+		}
+		return addr.String(), nil
+
+	case Taproot:
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		addr, err := btcutil.NewAddressTaproot(
+			schnorrSerialize(outputKey), &CHAIN_CONFIG)
+		if err != nil {
+			return "", err
+		}
+		return addr.String(), nil
+
+	default:
+		return "", fmt.Errorf("unknown address type: %d", t)
+	}
+}
+
+// p2wpkhRedeemScript returns the 22-byte native-SegWit witness program
+// (0x0014{hash160(pubkey)}) used directly as the redeemScript for a
+// P2SH-wrapped P2WPKH address.
+func p2wpkhRedeemScript(pubKey *btcec.PublicKey) ([]byte, error) {
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	return txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(pubKeyHash).
+		Script()
+}
+
+// schnorrSerialize returns the 32-byte x-only serialization of a pubkey,
+// as used in Taproot output keys and witness data.
+func schnorrSerialize(pubKey *btcec.PublicKey) []byte {
+	return pubKey.SerializeCompressed()[1:33]
+}
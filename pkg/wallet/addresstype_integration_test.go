@@ -0,0 +1,53 @@
+//go:build integration
+
+// Integration tests in this file hit Blockstream's live testnet Esplora API,
+// so they're excluded from normal `go test` runs:
+//
+//	go test -tags=integration ./pkg/wallet/...
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain/esplora"
+)
+
+// TestAddressTypeRoundTripAgainstEsplora derives an address for each
+// AddressType and queries it against the esplora backend, checking that a
+// real endpoint accepts the address format and returns a well-formed
+// result (rather than just exercising ClassifyScript offline, as
+// addresstype_test.go does).
+func TestAddressTypeRoundTripAgainstEsplora(t *testing.T) {
+	backend := esplora.New(esplora.DefaultTestnetURL)
+
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x03}, 32))
+	pubKey := privKey.PubKey()
+
+	tests := []struct {
+		name     string
+		addrType AddressType
+	}{
+		{"NativeSegWit", NativeSegWit},
+		{"NestedSegWit", NestedSegWit},
+		{"Taproot", Taproot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := tt.addrType.addressForPubKey(pubKey)
+			if err != nil {
+				t.Fatalf("addressForPubKey: %v", err)
+			}
+
+			if _, err := backend.AddressTxCount(address); err != nil {
+				t.Fatalf("AddressTxCount(%s): %v", address, err)
+			}
+			if _, err := backend.UTXOs(address); err != nil {
+				t.Fatalf("UTXOs(%s): %v", address, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TestAddressTypeRoundTrip derives an address for each AddressType and
+// checks that decoding it back to a pkScript and classifying that script
+// recovers the spending path the address type implies.
+func TestAddressTypeRoundTrip(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x01}, 32))
+	pubKey := privKey.PubKey()
+
+	tests := []struct {
+		name     string
+		addrType AddressType
+		want     ScriptType
+	}{
+		{"NativeSegWit", NativeSegWit, ScriptTypeP2WPKH},
+		{"NestedSegWit", NestedSegWit, ScriptTypeP2SH},
+		{"Taproot", Taproot, ScriptTypeP2TR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := tt.addrType.addressForPubKey(pubKey)
+			if err != nil {
+				t.Fatalf("addressForPubKey: %v", err)
+			}
+
+			addr, err := btcutil.DecodeAddress(address, &CHAIN_CONFIG)
+			if err != nil {
+				t.Fatalf("DecodeAddress(%s): %v", address, err)
+			}
+			pkScript, err := txscript.PayToAddrScript(addr)
+			if err != nil {
+				t.Fatalf("PayToAddrScript: %v", err)
+			}
+
+			if got := ClassifyScript(pkScript); got != tt.want {
+				t.Errorf("ClassifyScript(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTaprootOutputKeyMatchesSignerTweak checks that the output key
+// addressForPubKey derives for a Taproot address (via
+// txscript.ComputeTaprootKeyNoScript on the public key) is the same key
+// LocalSigner.SignSchnorr signs with (via txscript.TweakTaprootPrivKey on
+// the private key). If these ever disagreed, a Taproot wallet would derive
+// an address for a key it can't actually produce valid signatures for.
+func TestTaprootOutputKeyMatchesSignerTweak(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x02}, 32))
+	pubKey := privKey.PubKey()
+
+	wantOutputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+	tweakedPriv := txscript.TweakTaprootPrivKey(*privKey, nil)
+
+	got := schnorrSerialize(tweakedPriv.PubKey())
+	want := schnorrSerialize(wantOutputKey)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("signer's tweaked output key %x does not match address output key %x", got, want)
+	}
+}
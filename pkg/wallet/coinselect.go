@@ -0,0 +1,211 @@
+package wallet
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Approximate per-input vbyte costs by script type, used both for coin
+// selection and fee estimation. P2TR is the only non-integer cost (its
+// witness is a lone 64-byte Schnorr signature instead of an ECDSA signature
+// plus a 33-byte public key, 42 witness bytes or 10.5 vbytes less).
+const (
+	p2wpkhInputVBytes     = 68
+	p2pkhInputVBytes      = 148
+	p2trInputVBytes       = 57.5
+	p2shP2wpkhInputVBytes = 91 // P2SH-wrapped P2WPKH (BIP-49): redeemScript push + witness
+)
+
+// dustLimit is the minimum output value considered economical to spend;
+// below this, a change output is folded into the fee instead.
+const dustLimit = int64(546)
+
+// bnbMaxTries caps how many nodes branchAndBound's DFS will visit before
+// giving up and falling back to single-random-draw. Subset-sum search is
+// worst-case exponential even with the prunes below; this matches the cap
+// Bitcoin Core uses for the same algorithm.
+const bnbMaxTries = 100_000
+
+// CoinSelector chooses which UTXOs fund a transaction, so alternative
+// strategies (oldest-first, privacy-preserving) can be plugged in without
+// touching CreateTransaction.
+type CoinSelector interface {
+	// SelectUTXOs picks a subset of utxos covering amount plus fees at
+	// feeRate for a transaction with numOutputs non-change outputs. It
+	// returns the selected UTXOs and the change amount to pay back to the
+	// wallet (zero if no change output is needed).
+	SelectUTXOs(utxos []UTXO, amount int64, feeRate float64, numOutputs int) (selected []UTXO, changeAmount int64, err error)
+}
+
+// BranchAndBoundSelector implements Murch's Branch-and-Bound algorithm,
+// which tries to find an exact-match subset of UTXOs so no change output
+// is needed. When no exact match exists it falls back to single-random-draw.
+type BranchAndBoundSelector struct {
+	DustLimit int64
+}
+
+// NewBranchAndBoundSelector returns a BnB selector using dustLimit as the
+// minimum economical change output.
+func NewBranchAndBoundSelector(dustLimit int64) *BranchAndBoundSelector {
+	return &BranchAndBoundSelector{DustLimit: dustLimit}
+}
+
+// inputVBytes returns the approximate vbyte cost of spending a UTXO with
+// the given pkScript.
+func inputVBytes(pkScript []byte) float64 {
+	switch ClassifyScript(pkScript) {
+	case ScriptTypeP2WPKH:
+		return p2wpkhInputVBytes
+	case ScriptTypeP2TR:
+		return p2trInputVBytes
+	case ScriptTypeP2SH:
+		return p2shP2wpkhInputVBytes
+	default:
+		return p2pkhInputVBytes // P2PKH and anything else we don't special-case
+	}
+}
+
+// bnbCandidate pairs a UTXO with its effective value (value minus the fee
+// needed to spend it at the target fee rate).
+type bnbCandidate struct {
+	utxo           UTXO
+	effectiveValue int64
+}
+
+// SelectUTXOs implements CoinSelector.
+func (s *BranchAndBoundSelector) SelectUTXOs(utxos []UTXO, amount int64, feeRate float64, numOutputs int) ([]UTXO, int64, error) {
+	if len(utxos) == 0 {
+		return nil, 0, errors.New("no UTXOs available")
+	}
+
+	candidates := make([]bnbCandidate, len(utxos))
+	for i, utxo := range utxos {
+		candidates[i] = bnbCandidate{
+			utxo:           utxo,
+			effectiveValue: utxo.Value - round(feeRate*inputVBytes(utxo.PkScript)),
+		}
+	}
+	sortCandidatesDescending(candidates)
+
+	target := amount + round(feeRate*(10+31*float64(numOutputs)))
+	costOfChange := s.DustLimit + round(feeRate*31)
+
+	if selected, ok := branchAndBound(candidates, target, costOfChange); ok {
+		return selected, 0, nil
+	}
+
+	return singleRandomDraw(utxos, amount, feeRate, numOutputs, s.DustLimit)
+}
+
+func sortCandidatesDescending(candidates []bnbCandidate) {
+	for i := 1; i < len(candidates); i++ {
+		for j := i; j > 0 && candidates[j].effectiveValue > candidates[j-1].effectiveValue; j-- {
+			candidates[j], candidates[j-1] = candidates[j-1], candidates[j]
+		}
+	}
+}
+
+// branchAndBound performs a depth-first search over candidates (sorted by
+// descending effective value), including or excluding each in turn, and
+// returns the first subset whose effective value sums to within
+// [target, target+costOfChange]. The search aborts and reports failure
+// after visiting bnbMaxTries nodes rather than exhausting a worst-case
+// exponential tree.
+func branchAndBound(candidates []bnbCandidate, target, costOfChange int64) ([]UTXO, bool) {
+	// remaining[i] is the sum of effective values of candidates[i:], used to
+	// prune branches that can never reach the target.
+	remaining := make([]int64, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + candidates[i].effectiveValue
+	}
+
+	var selected []UTXO
+	tries := 0
+	var search func(index int, sum int64) bool
+	search = func(index int, sum int64) bool {
+		tries++
+		if tries > bnbMaxTries {
+			return false // exploration cap hit, give up and fall back to SRD
+		}
+		if sum > target+costOfChange {
+			return false // overshoot, prune
+		}
+		if sum >= target {
+			return true // exact match within tolerance
+		}
+		if index >= len(candidates) || sum+remaining[index] < target {
+			return false // can never reach target from here, prune
+		}
+
+		// Branch 1: include candidates[index].
+		selected = append(selected, candidates[index].utxo)
+		if search(index+1, sum+candidates[index].effectiveValue) {
+			return true
+		}
+		selected = selected[:len(selected)-1]
+
+		// Branch 2: exclude candidates[index].
+		return search(index+1, sum)
+	}
+
+	if search(0, 0) {
+		return selected, true
+	}
+	return nil, false
+}
+
+// singleRandomDraw is the knapsack fallback used when Branch-and-Bound
+// cannot find an exact match: shuffle the UTXOs, accumulate effective value
+// (value minus the fee needed to spend each input) until the target is
+// covered, and only emit a change output if it clears the dust limit
+// (otherwise the excess is simply left to the miner fee).
+func singleRandomDraw(utxos []UTXO, amount int64, feeRate float64, numOutputs int, dustLimit int64) ([]UTXO, int64, error) {
+	shuffled := make([]UTXO, len(utxos))
+	copy(shuffled, utxos)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	var selected []UTXO
+	var effectiveTotal int64
+	target := amount + round(feeRate*(10+31*float64(numOutputs)))
+	for _, utxo := range shuffled {
+		selected = append(selected, utxo)
+		effectiveTotal += utxo.Value - round(feeRate*inputVBytes(utxo.PkScript))
+		if effectiveTotal >= target {
+			break
+		}
+	}
+
+	var total int64
+	for _, utxo := range selected {
+		total += utxo.Value
+	}
+
+	// A change output adds another 31 vbytes to the fee; only count it in
+	// once we know the leftover will actually clear the dust limit.
+	fee := EstimateFee(selected, numOutputs, feeRate)
+	if total-amount-fee >= dustLimit {
+		fee = EstimateFee(selected, numOutputs+1, feeRate)
+	}
+
+	if total < amount+fee {
+		return nil, 0, errors.New("insufficient funds: no combination of UTXOs covers the amount and fees")
+	}
+
+	change := total - amount - fee
+	if change < dustLimit {
+		change = 0
+	}
+
+	return selected, change, nil
+}
+
+// round rounds a float fee/vbyte computation to the nearest integer, the
+// way sat/vbyte fee math is conventionally rounded.
+func round(f float64) int64 {
+	if f < 0 {
+		return int64(f - 0.5)
+	}
+	return int64(f + 0.5)
+}
@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// testUTXO returns a UTXO with a P2WPKH pkScript, the only detail
+// ClassifyScript/inputVBytes inspect; the hash bytes themselves don't
+// matter for coin selection.
+func testUTXO(index uint32, value int64) UTXO {
+	script := make([]byte, 22)
+	script[1] = 0x14 // 0x00 0x14 <20-byte hash> is the P2WPKH witness program
+	return UTXO{
+		OutPoint: wire.OutPoint{Index: index},
+		Value:    value,
+		PkScript: script,
+	}
+}
+
+func TestBranchAndBoundExactMatch(t *testing.T) {
+	const amount, feeRate, numOutputs = int64(10000), 1.0, 1
+
+	target := amount + round(feeRate*(10+31*float64(numOutputs)))
+	costOfChange := dustLimit + round(feeRate*31)
+
+	// Single candidate whose effective value lands exactly on target: BnB
+	// should take it with no change output.
+	candidates := []bnbCandidate{
+		{utxo: testUTXO(0, target+68), effectiveValue: target},
+	}
+
+	selected, ok := branchAndBound(candidates, target, costOfChange)
+	if !ok {
+		t.Fatalf("expected an exact match, got none")
+	}
+	if len(selected) != 1 {
+		t.Fatalf("expected 1 selected UTXO, got %d", len(selected))
+	}
+
+	selector := NewBranchAndBoundSelector(dustLimit)
+	gotSelected, changeAmount, err := selector.SelectUTXOs([]UTXO{testUTXO(0, target+68)}, amount, feeRate, numOutputs)
+	if err != nil {
+		t.Fatalf("SelectUTXOs: %v", err)
+	}
+	if changeAmount != 0 {
+		t.Errorf("changeAmount = %d, want 0 for an exact match", changeAmount)
+	}
+	if len(gotSelected) != 1 {
+		t.Errorf("selected %d UTXOs, want 1", len(gotSelected))
+	}
+}
+
+func TestBranchAndBoundOvershootPrune(t *testing.T) {
+	const amount, feeRate, numOutputs = int64(10000), 1.0, 1
+
+	target := amount + round(feeRate*(10+31*float64(numOutputs)))
+	costOfChange := dustLimit + round(feeRate*31)
+
+	// Neither candidate alone reaches target, but together they overshoot
+	// past target+costOfChange, so branchAndBound must prune that branch
+	// and report no match (falling back to SRD upstream).
+	candidates := []bnbCandidate{
+		{utxo: testUTXO(0, 6068), effectiveValue: 6000},
+		{utxo: testUTXO(1, 5068), effectiveValue: 5000},
+	}
+
+	if _, ok := branchAndBound(candidates, target, costOfChange); ok {
+		t.Fatalf("expected no match (overshoot should be pruned), got a match")
+	}
+}
+
+func TestBranchAndBoundTriesCap(t *testing.T) {
+	// Many UTXOs of equal value with no exact-match subset forces the DFS
+	// to explore deeply; it must still return promptly via the tries cap
+	// rather than exhausting the exponential search space. target is
+	// 15*999+1 and the window is 577 wide, so 15 candidates fall 1 short
+	// and 16 overshoot, while all 30 together still exceed target.
+	const amount, feeRate, numOutputs = int64(14_945), 1.0, 1
+
+	candidates := make([]bnbCandidate, 30)
+	for i := range candidates {
+		candidates[i] = bnbCandidate{utxo: testUTXO(uint32(i), 1000), effectiveValue: 999}
+	}
+
+	target := amount + round(feeRate*(10+31*float64(numOutputs)))
+	costOfChange := dustLimit + round(feeRate*31)
+
+	if _, ok := branchAndBound(candidates, target, costOfChange); ok {
+		t.Fatalf("expected no match (no subset lands in the target window), got a match")
+	}
+}
+
+func TestSingleRandomDrawFoldsDustChangeToZero(t *testing.T) {
+	const amount, feeRate, numOutputs = int64(1000), 1.0, 1
+
+	// total(1200) - amount(1000) - fee(110) = 90, below dustLimit, so it
+	// should be folded into the fee rather than emitted as change.
+	selected, change, err := singleRandomDraw([]UTXO{testUTXO(0, 1200)}, amount, feeRate, numOutputs, dustLimit)
+	if err != nil {
+		t.Fatalf("singleRandomDraw: %v", err)
+	}
+	if len(selected) != 1 {
+		t.Fatalf("selected %d UTXOs, want 1", len(selected))
+	}
+	if change != 0 {
+		t.Errorf("change = %d, want 0 (below dust limit)", change)
+	}
+}
+
+func TestSingleRandomDrawRecomputesFeeForChangeOutput(t *testing.T) {
+	const amount, feeRate, numOutputs = int64(1000), 1.0, 1
+
+	// total(2000) - amount(1000) - fee(110, 1 output) = 890, clears the
+	// dust limit, so the fee must be recomputed for numOutputs+1 (the
+	// change output) before the final change is derived.
+	selected, change, err := singleRandomDraw([]UTXO{testUTXO(0, 2000)}, amount, feeRate, numOutputs, dustLimit)
+	if err != nil {
+		t.Fatalf("singleRandomDraw: %v", err)
+	}
+
+	wantFee := EstimateFee(selected, numOutputs+1, feeRate)
+	wantChange := int64(2000) - amount - wantFee
+	if change != wantChange {
+		t.Errorf("change = %d, want %d (fee recomputed for %d outputs)", change, wantChange, numOutputs+1)
+	}
+}
+
+func TestSingleRandomDrawInsufficientFunds(t *testing.T) {
+	const amount, feeRate, numOutputs = int64(1_000_000), 1.0, 1
+
+	_, _, err := singleRandomDraw([]UTXO{testUTXO(0, 1000)}, amount, feeRate, numOutputs, dustLimit)
+	if err == nil {
+		t.Fatal("expected an error for insufficient funds, got nil")
+	}
+}
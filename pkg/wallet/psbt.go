@@ -0,0 +1,205 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BuildPSBT builds an unsigned PSBT (BIP-174) sending amount to
+// receiverAddress, funded from this wallet's UTXOs. Each input is populated
+// with its WitnessUtxo, SighashType and Bip32Derivation so that an offline
+// signer (or the MPC service) can later produce PartialSigs without any
+// further context. It does not sign anything itself.
+func (w *Wallet) BuildPSBT(receiverAddress string, amount int64) (*psbt.Packet, error) {
+	if w.addrType == Taproot {
+		return nil, fmt.Errorf("BuildPSBT does not support Taproot wallets yet")
+	}
+
+	feeRate, err := w.backend.FeeRate(1)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedUTXOs, changeAmount, err := selectUTXOs(w.backend, w.address, amount, feeRate, 1, w.selector)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := btcutil.DecodeAddress(receiverAddress, &CHAIN_CONFIG)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address: %v", err)
+	}
+	receiverScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pay-to-address script: %v", err)
+	}
+
+	txOuts := []*wire.TxOut{wire.NewTxOut(amount, receiverScript)}
+	if changeAmount > 0 {
+		changeAddr, err := btcutil.DecodeAddress(w.address, &CHAIN_CONFIG)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode change address: %v", err)
+		}
+		changeScript, err := txscript.PayToAddrScript(changeAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create change script: %v", err)
+		}
+		txOuts = append(txOuts, wire.NewTxOut(changeAmount, changeScript))
+	}
+
+	outPoints := make([]*wire.OutPoint, len(selectedUTXOs))
+	sequences := make([]uint32, len(selectedUTXOs))
+	for i := range selectedUTXOs {
+		outPoints[i] = &selectedUTXOs[i].OutPoint
+		sequences[i] = wire.MaxTxInSequenceNum
+	}
+
+	p, err := psbt.New(outPoints, txOuts, wire.TxVersion, 0, sequences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PSBT: %v", err)
+	}
+
+	pubKeyData := w.pubKey.SerializeCompressed()
+	var redeemScript []byte
+	if w.addrType == NestedSegWit {
+		redeemScript, err = p2wpkhRedeemScript(w.pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build redeem script: %v", err)
+		}
+	}
+
+	for i, utxo := range selectedUTXOs {
+		p.Inputs[i].WitnessUtxo = &wire.TxOut{
+			Value:    utxo.Value,
+			PkScript: utxo.PkScript,
+		}
+		p.Inputs[i].SighashType = txscript.SigHashAll
+		p.Inputs[i].RedeemScript = redeemScript
+		p.Inputs[i].Bip32Derivation = []*psbt.Bip32Derivation{
+			{
+				PubKey:    pubKeyData,
+				Bip32Path: w.derivationPath,
+			},
+		}
+	}
+
+	return p, nil
+}
+
+// SignPSBT fills in PartialSigs for every native or P2SH-wrapped SegWit
+// input this wallet's signer controls, leaving the rest untouched so other
+// signers can do the same for their own inputs. Taproot PSBT inputs aren't
+// supported yet; BuildPSBT only ever produces them for non-Taproot wallets.
+func (w *Wallet) SignPSBT(p *psbt.Packet) error {
+	if w.signer == nil {
+		return fmt.Errorf("wallet is watch-only and cannot sign PSBTs")
+	}
+
+	pubKeyData := w.signer.PublicKey().SerializeCompressed()
+
+	// ownScript is the P2WPKH witness program for the signer's key: the
+	// pkScript of a native input it controls, or the redeemScript of a
+	// P2SH-wrapped one.
+	ownScript, err := p2wpkhRedeemScript(w.signer.PublicKey())
+	if err != nil {
+		return fmt.Errorf("failed to build signer's witness program: %v", err)
+	}
+
+	tx := p.UnsignedTx
+	fetcher := psbtPrevOutputFetcher{p: p}
+	hashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	for i, in := range p.Inputs {
+		if in.WitnessUtxo == nil {
+			continue
+		}
+
+		scriptType := ClassifyScript(in.WitnessUtxo.PkScript)
+		var scriptCode []byte
+		switch scriptType {
+		case ScriptTypeP2WPKH:
+			if !bytes.Equal(in.WitnessUtxo.PkScript, ownScript) {
+				continue // not ours: pays a different key
+			}
+			scriptCode = in.WitnessUtxo.PkScript
+		case ScriptTypeP2SH:
+			if !bytes.Equal(in.RedeemScript, ownScript) {
+				continue // not ours: missing or different redeemScript
+			}
+			scriptCode = in.RedeemScript
+		default:
+			continue // P2TR and anything else aren't handled here yet
+		}
+
+		hash, err := txscript.CalcWitnessSigHash(scriptCode, hashes, in.SighashType, tx, i, in.WitnessUtxo.Value)
+		if err != nil {
+			return fmt.Errorf("failed to calculate signature hash for input %d: %v", i, err)
+		}
+
+		rawSig, err := w.signer.Sign(hash, in.SighashType)
+		if err != nil {
+			return fmt.Errorf("failed to sign input %d: %v", i, err)
+		}
+		sig := append(rawSig.Serialize(), byte(in.SighashType))
+
+		p.Inputs[i].PartialSigs = append(p.Inputs[i].PartialSigs, &psbt.PartialSig{
+			PubKey:    pubKeyData,
+			Signature: sig,
+		})
+	}
+
+	return nil
+}
+
+// FinalizePSBT assembles the final witnesses (and, for P2SH-wrapped
+// inputs, the redeemScript push) from PartialSigs and returns the
+// broadcast-ready serialized transaction.
+func (w *Wallet) FinalizePSBT(p *psbt.Packet) ([]byte, error) {
+	tx := p.UnsignedTx.Copy()
+
+	for i, in := range p.Inputs {
+		if len(in.PartialSigs) == 0 {
+			return nil, fmt.Errorf("input %d has no partial signatures", i)
+		}
+		// Single-signer inputs: exactly one partial sig per input.
+		partialSig := in.PartialSigs[0]
+		tx.TxIn[i].Witness = wire.TxWitness{
+			partialSig.Signature,
+			partialSig.PubKey,
+		}
+		if len(in.RedeemScript) > 0 {
+			sigScript, err := txscript.NewScriptBuilder().AddData(in.RedeemScript).Script()
+			if err != nil {
+				return nil, fmt.Errorf("failed to build signature script for input %d: %v", i, err)
+			}
+			tx.TxIn[i].SignatureScript = sigScript
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize finalized transaction: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// psbtPrevOutputFetcher adapts a PSBT's WitnessUtxo set to the
+// txscript.PrevOutputFetcher interface required for sighash computation.
+type psbtPrevOutputFetcher struct {
+	p *psbt.Packet
+}
+
+func (f psbtPrevOutputFetcher) FetchPrevOutput(op wire.OutPoint) *wire.TxOut {
+	for i, txIn := range f.p.UnsignedTx.TxIn {
+		if txIn.PreviousOutPoint == op {
+			return f.p.Inputs[i].WitnessUtxo
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	bip32 "github.com/tyler-smith/go-bip32"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain"
+)
+
+// DefaultGapLimit is the BIP-44 gap limit: the number of consecutive
+// unused addresses scanned before giving up on finding more.
+const DefaultGapLimit = 20
+
+// scanConcurrency bounds how many address lookups are in flight at once,
+// so scanning a fresh wallet doesn't serialize hundreds of round-trips
+// but also doesn't hammer the API.
+const scanConcurrency = 8
+
+const (
+	externalChainIndex uint32 = 0
+	internalChainIndex uint32 = 1
+)
+
+// DerivedUTXO is a UTXO together with the derivation path of the key that
+// controls it, needed to sign with the matching child key.
+type DerivedUTXO struct {
+	UTXO
+	DerivationPath []uint32
+}
+
+// WalletState is the result of scanning an account: every UTXO found
+// across both chains, and the next unused address on each.
+type WalletState struct {
+	UTXOs               []DerivedUTXO
+	NextExternalAddress string
+	NextExternalPath    []uint32
+	NextChangeAddress   string
+	NextChangePath      []uint32
+}
+
+// Scanner discovers addresses and UTXOs for an account-level extended key
+// by walking the external and internal chains until GapLimit consecutive
+// unused addresses are found on each.
+type Scanner struct {
+	AddrType AddressType
+	GapLimit int
+	Backend  chain.Backend
+}
+
+// NewScanner returns a Scanner for addrType using the default gap limit,
+// querying backend for address history and UTXOs.
+func NewScanner(addrType AddressType, backend chain.Backend) *Scanner {
+	return &Scanner{AddrType: addrType, GapLimit: DefaultGapLimit, Backend: backend}
+}
+
+// Scan walks both chains under accountKey (an xprv or xpub at
+// m/purpose'/0'/0') and returns the aggregated WalletState.
+func (s *Scanner) Scan(accountKey *bip32.Key) (*WalletState, error) {
+	externalUTXOs, nextExternal, nextExternalPath, err := s.scanChain(accountKey, externalChainIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan external chain: %v", err)
+	}
+
+	internalUTXOs, nextInternal, nextInternalPath, err := s.scanChain(accountKey, internalChainIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan internal chain: %v", err)
+	}
+
+	return &WalletState{
+		UTXOs:               append(externalUTXOs, internalUTXOs...),
+		NextExternalAddress: nextExternal,
+		NextExternalPath:    nextExternalPath,
+		NextChangeAddress:   nextInternal,
+		NextChangePath:      nextInternalPath,
+	}, nil
+}
+
+// addressLookup is the result of deriving and querying a single address.
+type addressLookup struct {
+	address string
+	path    []uint32
+	used    bool
+	utxos   []DerivedUTXO
+}
+
+// scanChain walks chainIndex (0=external, 1=internal) under accountKey in
+// batches of GapLimit addresses, fetched concurrently, stopping once a
+// whole batch comes back with no transaction history.
+func (s *Scanner) scanChain(accountKey *bip32.Key, chainIndex uint32) ([]DerivedUTXO, string, []uint32, error) {
+	chainKey, err := accountKey.NewChildKey(chainIndex)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to derive chain %d: %v", chainIndex, err)
+	}
+
+	var utxos []DerivedUTXO
+	lastUsedIndex := -1
+
+	for batchStart := uint32(0); ; batchStart += uint32(s.GapLimit) {
+		batch, err := s.lookupBatch(chainKey, chainIndex, batchStart, uint32(s.GapLimit))
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		anyUsed := false
+		for i, lookup := range batch {
+			if lookup.used {
+				anyUsed = true
+				lastUsedIndex = int(batchStart) + i
+			}
+			utxos = append(utxos, lookup.utxos...)
+		}
+
+		if !anyUsed {
+			break
+		}
+	}
+
+	nextIndex := uint32(lastUsedIndex + 1)
+	nextAddress, nextPath, err := s.deriveAt(chainKey, chainIndex, nextIndex)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to derive next unused address: %v", err)
+	}
+
+	return utxos, nextAddress, nextPath, nil
+}
+
+// lookupBatch derives and queries count consecutive addresses starting at
+// index, with at most scanConcurrency requests in flight at once.
+func (s *Scanner) lookupBatch(chainKey *bip32.Key, chainIndex, start, count uint32) ([]addressLookup, error) {
+	results := make([]addressLookup, count)
+
+	g, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, scanConcurrency)
+
+	for i := uint32(0); i < count; i++ {
+		index := start + i
+		slot := i
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-sem }()
+
+			address, path, err := s.deriveAt(chainKey, chainIndex, index)
+			if err != nil {
+				return err
+			}
+
+			txCount, err := s.Backend.AddressTxCount(address)
+			if err != nil {
+				return err
+			}
+
+			utxos, err := s.Backend.UTXOs(address)
+			if err != nil {
+				return err
+			}
+
+			derived := make([]DerivedUTXO, len(utxos))
+			for j, utxo := range utxos {
+				derived[j] = DerivedUTXO{UTXO: utxo, DerivationPath: path}
+			}
+
+			results[slot] = addressLookup{
+				address: address,
+				path:    path,
+				used:    txCount > 0,
+				utxos:   derived,
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to scan addresses: %v", err)
+	}
+
+	return results, nil
+}
+
+// deriveAt derives the address and full BIP-44 path for chainKey/index.
+func (s *Scanner) deriveAt(chainKey *bip32.Key, chainIndex, index uint32) (string, []uint32, error) {
+	child, err := chainKey.NewChildKey(index)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pubKey, err := childPubKey(child)
+	if err != nil {
+		return "", nil, err
+	}
+
+	address, err := s.AddrType.addressForPubKey(pubKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	path, err := s.AddrType.derivationPath(chainIndex, index)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return address, path, nil
+}
+
+// childPubKey returns the public key for a derived bip32.Key, which may
+// hold either a private key (xprv-derived) or a public key (xpub-derived).
+func childPubKey(key *bip32.Key) (*btcec.PublicKey, error) {
+	if key.IsPrivate {
+		privKey, _ := btcec.PrivKeyFromBytes(key.Key)
+		return privKey.PubKey(), nil
+	}
+	return btcec.ParsePubKey(key.Key)
+}
+
+// deriveChildSigner derives the private key at accountKey/chainIndex/index
+// and wraps it as a Signer, so each UTXO found by the scanner can be signed
+// with the specific child key that controls it.
+func deriveChildSigner(accountKey *bip32.Key, chainIndex, index uint32) (Signer, error) {
+	if !accountKey.IsPrivate {
+		return nil, fmt.Errorf("cannot sign: account key has no private key material")
+	}
+
+	key, err := accountKey.NewChildKey(chainIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive chain %d: %v", chainIndex, err)
+	}
+	key, err = key.NewChildKey(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive index %d: %v", index, err)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(key.Key)
+	return NewLocalSigner(privKey), nil
+}
@@ -0,0 +1,68 @@
+package wallet
+
+import (
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain"
+)
+
+// UTXO is a chain.UTXO, reexported so callers in this package don't need
+// to import pkg/chain themselves.
+type UTXO = chain.UTXO
+
+// ScriptType classifies a pkScript by the spending path it requires.
+type ScriptType int
+
+const (
+	ScriptTypeP2PKH ScriptType = iota
+	ScriptTypeP2SH             // includes P2SH-wrapped SegWit (BIP-49)
+	ScriptTypeP2WPKH
+	ScriptTypeP2WSH
+	ScriptTypeP2TR
+	ScriptTypeUnknown
+)
+
+// ClassifyScript identifies the spending path a pkScript requires.
+func ClassifyScript(script []byte) ScriptType {
+	switch {
+	case len(script) == 25 && script[0] == 0x76 && script[1] == 0xA9:
+		return ScriptTypeP2PKH
+	case len(script) == 23 && script[0] == 0xA9 && script[22] == 0x87:
+		return ScriptTypeP2SH
+	case len(script) == 22 && script[0] == 0x00 && script[1] == 0x14:
+		return ScriptTypeP2WPKH
+	case len(script) == 34 && script[0] == 0x00 && script[1] == 0x20:
+		return ScriptTypeP2WSH
+	case len(script) == 34 && script[0] == 0x51 && script[1] == 0x20:
+		return ScriptTypeP2TR
+	default:
+		return ScriptTypeUnknown
+	}
+}
+
+// EstimateFee estimates the fee for a transaction, accounting for each
+// input's script type (P2WPKH, P2PKH, P2TR each cost a different number of
+// vbytes to spend) rather than assuming a single flat input size.
+func EstimateFee(utxos []UTXO, numOutputs int, feeRate float64) int64 {
+	vsize := 10.0
+	for _, utxo := range utxos {
+		vsize += inputVBytes(utxo.PkScript)
+	}
+	vsize += 31 * float64(numOutputs)
+	return int64(vsize*feeRate) + 1
+}
+
+// utxoFetcher implements txscript.PrevOutputFetcher over a fixed set of
+// UTXOs, used to compute sighashes during signing.
+type utxoFetcher struct {
+	utxos []UTXO
+}
+
+func (f utxoFetcher) FetchPrevOutput(outPoint wire.OutPoint) *wire.TxOut {
+	for _, utxo := range f.utxos {
+		if utxo.OutPoint == outPoint {
+			return &wire.TxOut{Value: utxo.Value, PkScript: utxo.PkScript}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,475 @@
+// Package wallet holds the Wallet type and the transaction construction,
+// coin selection, and signing logic built on top of it. It talks to the
+// network only through a chain.Backend, so it works the same whether that
+// backend is Esplora, Electrum, or anything else.
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	bip32 "github.com/tyler-smith/go-bip32"
+	bip39 "github.com/tyler-smith/go-bip39"
+
+	"github.com/zkrypt-crossbar/btc-demo-go/pkg/chain"
+)
+
+const (
+	HardenedOffset = 0x80000000 // 2^31 in hexadecimal
+)
+
+var CHAIN_CONFIG = chaincfg.TestNet3Params
+
+// Wallet represents a Bitcoin wallet. Signing for its primary key is
+// delegated to a Signer, which may be a local key or a remote MPC service.
+// A wallet created from a mnemonic also holds the account-level extended
+// private key (accountKey), from which CreateTransaction derives a local
+// signer per input; wallets from NewWalletFromPublicKey hold no private
+// key material.
+type Wallet struct {
+	signer         Signer
+	selector       CoinSelector
+	backend        chain.Backend
+	addrType       AddressType
+	address        string
+	pubKey         *btcec.PublicKey
+	derivationPath []uint32
+
+	// accountKey is the account-level extended key (m/purpose'/0'/0'), used
+	// to scan and sign across every derived address rather than just
+	// address. It is nil for wallets built around a single already-derived
+	// key (NewWalletFromPublicKey), which can't discover or sign for any
+	// other index.
+	accountKey *bip32.Key
+}
+
+// NewWallet creates a new wallet from a mnemonic, deriving a local signing
+// key that lives in this process for the given address type.
+func NewWallet(mnemonic string, addrType AddressType, backend chain.Backend) (*Wallet, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid mnemonic: %s", mnemonic)
+	}
+
+	privKey, address, derivationPath, err := deriveAddress(mnemonic, addrType)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving address: %v", err)
+	}
+
+	accountKey, err := deriveAccountKey(mnemonic, addrType)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving account key: %v", err)
+	}
+
+	return &Wallet{
+		signer:         NewLocalSigner(privKey),
+		selector:       NewBranchAndBoundSelector(dustLimit),
+		backend:        backend,
+		addrType:       addrType,
+		address:        address,
+		pubKey:         privKey.PubKey(),
+		derivationPath: derivationPath,
+		accountKey:     accountKey,
+	}, nil
+}
+
+// NewWalletFromPublicKey creates a watch-only wallet around a public key
+// and a remote Signer (e.g. an MPCSigner). No mnemonic or private key
+// material is required or held by this process.
+func NewWalletFromPublicKey(pubKey *btcec.PublicKey, signer Signer, addrType AddressType, backend chain.Backend) (*Wallet, error) {
+	address, err := addrType.addressForPubKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving address: %v", err)
+	}
+	derivationPath, err := addrType.derivationPath(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error computing derivation path: %v", err)
+	}
+
+	return &Wallet{
+		signer:         signer,
+		selector:       NewBranchAndBoundSelector(dustLimit),
+		backend:        backend,
+		addrType:       addrType,
+		address:        address,
+		pubKey:         pubKey,
+		derivationPath: derivationPath,
+	}, nil
+}
+
+// NewWatchOnlyWallet creates a wallet from an account-level extended public
+// key (e.g. m/84'/0'/0') with no signing capability at all. It can build
+// and finalize PSBTs but SignPSBT will always fail; an offline signer or
+// the MPC service is expected to fill in the PartialSigs out of band.
+func NewWatchOnlyWallet(xpub string, addrType AddressType, backend chain.Backend) (*Wallet, error) {
+	accountKey, err := bip32.B58Deserialize(xpub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpub: %v", err)
+	}
+
+	externalChain, err := accountKey.NewChildKey(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive external chain: %v", err)
+	}
+	firstKey, err := externalChain.NewChildKey(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive first address: %v", err)
+	}
+
+	pubKey, err := btcec.ParsePubKey(firstKey.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse derived public key: %v", err)
+	}
+
+	address, err := addrType.addressForPubKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving address: %v", err)
+	}
+	derivationPath, err := addrType.derivationPath(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error computing derivation path: %v", err)
+	}
+
+	return &Wallet{
+		signer:         nil,
+		selector:       NewBranchAndBoundSelector(dustLimit),
+		backend:        backend,
+		addrType:       addrType,
+		address:        address,
+		pubKey:         pubKey,
+		derivationPath: derivationPath,
+		accountKey:     accountKey,
+	}, nil
+}
+
+// Address returns the wallet's primary (index 0) address.
+func (w *Wallet) Address() string {
+	return w.address
+}
+
+// PublicKey returns the wallet's primary (index 0) public key.
+func (w *Wallet) PublicKey() *btcec.PublicKey {
+	return w.pubKey
+}
+
+// deriveAddress derives the first address (index 0) for mnemonic under
+// addrType's BIP-44-style path, e.g. m/84'/0'/0'/0/0 for NativeSegWit.
+func deriveAddress(mnemonic string, addrType AddressType) (*btcec.PrivateKey, string, []uint32, error) {
+	seed := bip39.NewSeed(mnemonic, "")
+
+	masterKey, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	path, err := addrType.derivationPath(0, 0)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	key := masterKey
+	for _, childIndex := range path {
+		key, err = key.NewChildKey(childIndex)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(key.Key)
+
+	address, err := addrType.addressForPubKey(privKey.PubKey())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return privKey, address, path, nil
+}
+
+// deriveAccountKey derives the account-level extended key m/purpose'/0'/0'
+// for mnemonic under addrType, used to scan and sign across every address
+// the wallet has ever used rather than just index 0.
+func deriveAccountKey(mnemonic string, addrType AddressType) (*bip32.Key, error) {
+	seed := bip39.NewSeed(mnemonic, "")
+
+	masterKey, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := addrType.accountPath()
+	if err != nil {
+		return nil, err
+	}
+
+	key := masterKey
+	for _, childIndex := range path {
+		key, err = key.NewChildKey(childIndex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// CreateTransaction creates and signs a new Bitcoin transaction. If the
+// wallet has an account-level key, it scans every derived address for
+// UTXOs and signs each input with the child key that actually controls it,
+// sending any change to the next unused internal (change) address. Wallets
+// built around a single key (NewWalletFromPublicKey) fall back to the
+// simpler single-address flow.
+func (w *Wallet) CreateTransaction(receiverAddress string, amount int64) ([]byte, error) {
+	if w.accountKey == nil {
+		return w.createSingleAddressTransaction(receiverAddress, amount)
+	}
+
+	feeRate, err := w.backend.FeeRate(1)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := NewScanner(w.addrType, w.backend).Scan(w.accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan wallet: %v", err)
+	}
+
+	utxos := make([]UTXO, len(state.UTXOs))
+	pathByOutPoint := make(map[wire.OutPoint][]uint32, len(state.UTXOs))
+	for i, derived := range state.UTXOs {
+		utxos[i] = derived.UTXO
+		pathByOutPoint[derived.OutPoint] = derived.DerivationPath
+	}
+
+	// numOutputs is 1 here (just the receiver output below): coin selection
+	// tries to avoid a change output entirely, and only adds one below if
+	// the selector reports it's needed.
+	selectedUTXOs, changeAmount, err := w.selector.SelectUTXOs(utxos, amount, feeRate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := addOutput(tx, receiverAddress, amount); err != nil {
+		return nil, err
+	}
+	if err := addInputs(tx, selectedUTXOs); err != nil {
+		return nil, err
+	}
+
+	fee := EstimateFee(selectedUTXOs, 1, feeRate)
+	log.Printf("Fee: %d", fee)
+
+	if changeAmount > 0 {
+		if err := addOutput(tx, state.NextChangeAddress, changeAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	fetcher := utxoFetcher{utxos: selectedUTXOs}
+	hashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, utxo := range selectedUTXOs {
+		path, ok := pathByOutPoint[utxo.OutPoint]
+		if !ok || len(path) < 2 {
+			return nil, fmt.Errorf("no derivation path recorded for input %d", i)
+		}
+		signer, err := deriveChildSigner(w.accountKey, path[len(path)-2], path[len(path)-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive signer for input %d: %v", i, err)
+		}
+		if err := signInput(tx, hashes, fetcher, i, utxo, signer); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// createSingleAddressTransaction is the original CreateTransaction flow,
+// kept for wallets that have no account-level key to scan with.
+func (w *Wallet) createSingleAddressTransaction(receiverAddress string, amount int64) ([]byte, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+
+	if err := addOutput(tx, receiverAddress, amount); err != nil {
+		return nil, err
+	}
+
+	feeRate, err := w.backend.FeeRate(1)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedUTXOs, changeAmount, err := selectUTXOs(w.backend, w.address, amount, feeRate, 1, w.selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addInputs(tx, selectedUTXOs); err != nil {
+		return nil, err
+	}
+
+	fee := EstimateFee(selectedUTXOs, 1, feeRate)
+	log.Printf("Fee: %d", fee)
+
+	if changeAmount > 0 {
+		if err := addOutput(tx, w.address, changeAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := signTransaction(tx, w.signer, selectedUTXOs); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Broadcast submits a transaction built by CreateTransaction (or
+// FinalizePSBT) to the network through this wallet's backend.
+func (w *Wallet) Broadcast(rawTx []byte) error {
+	return w.backend.Broadcast(rawTx)
+}
+
+func addOutput(tx *wire.MsgTx, receiverAddress string, amount int64) error {
+	addr, err := btcutil.DecodeAddress(receiverAddress, &CHAIN_CONFIG)
+	if err != nil {
+		return fmt.Errorf("failed to decode address: %v", err)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return fmt.Errorf("failed to create pay-to-address script: %v", err)
+	}
+
+	tx.AddTxOut(wire.NewTxOut(amount, pkScript))
+	return nil
+}
+
+// selectUTXOs fetches the UTXO set for address from backend and delegates
+// the actual selection to selector.
+func selectUTXOs(backend chain.Backend, address string, amount int64, feeRate float64, numOutputs int, selector CoinSelector) ([]UTXO, int64, error) {
+	utxos, err := backend.UTXOs(address)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get UTXOs: %v", err)
+	}
+
+	return selector.SelectUTXOs(utxos, amount, feeRate, numOutputs)
+}
+
+func addInputs(tx *wire.MsgTx, utxos []UTXO) error {
+	for _, utxo := range utxos {
+		tx.AddTxIn(wire.NewTxIn(&utxo.OutPoint, nil, nil))
+	}
+	return nil
+}
+
+// signTransaction signs every input of tx with a single signer, used by
+// wallets that control all their UTXOs with one key. Wallets spending
+// UTXOs from several derived addresses sign each input individually
+// instead; see signInput.
+func signTransaction(tx *wire.MsgTx, signer Signer, utxos []UTXO) error {
+	fetcher := utxoFetcher{utxos: utxos}
+	hashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	for i, utxo := range utxos {
+		if err := signInput(tx, hashes, fetcher, i, utxo, signer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signInput signs input i of tx, dispatching on its script type so native
+// SegWit, P2SH-wrapped SegWit, Taproot, and legacy P2PKH inputs can all be
+// spent in one transaction, each possibly with its own signer.
+func signInput(tx *wire.MsgTx, hashes *txscript.TxSigHashes, fetcher txscript.PrevOutputFetcher, i int, utxo UTXO, signer Signer) error {
+	txIn := tx.TxIn[i]
+
+	switch ClassifyScript(utxo.PkScript) {
+	case ScriptTypeP2WPKH:
+		return signWitnessInput(tx, txIn, hashes, i, utxo, utxo.PkScript, signer)
+
+	case ScriptTypeP2SH:
+		// P2SH-wrapped P2WPKH (BIP-49): the scriptCode for sighash
+		// purposes is the witness program, which also doubles as the
+		// redeemScript pushed into SignatureScript.
+		redeemScript, err := p2wpkhRedeemScript(signer.PublicKey())
+		if err != nil {
+			return fmt.Errorf("failed to build redeem script for input %d: %v", i, err)
+		}
+		if err := signWitnessInput(tx, txIn, hashes, i, utxo, redeemScript, signer); err != nil {
+			return err
+		}
+		sigScript, err := txscript.NewScriptBuilder().AddData(redeemScript).Script()
+		if err != nil {
+			return fmt.Errorf("failed to build signature script for input %d: %v", i, err)
+		}
+		txIn.SignatureScript = sigScript
+		return nil
+
+	case ScriptTypeP2TR:
+		hash, err := txscript.CalcTaprootSignatureHash(hashes, txscript.SigHashDefault, tx, i, fetcher)
+		if err != nil {
+			return fmt.Errorf("failed to calculate taproot signature hash for input %d: %v", i, err)
+		}
+		sig, err := signer.SignSchnorr(hash)
+		if err != nil {
+			return fmt.Errorf("failed to sign input %d: %v", i, err)
+		}
+		txIn.Witness = wire.TxWitness{sig.Serialize()}
+		return nil
+
+	default:
+		hash, err := txscript.CalcSignatureHash(utxo.PkScript, txscript.SigHashAll, tx, i)
+		if err != nil {
+			return fmt.Errorf("failed to calculate signature hash for input %d: %v", i, err)
+		}
+		rawSig, err := signer.Sign(hash, txscript.SigHashAll)
+		if err != nil {
+			return fmt.Errorf("failed to sign input %d: %v", i, err)
+		}
+		sig := append(rawSig.Serialize(), byte(txscript.SigHashAll))
+
+		pkData := signer.PublicKey().SerializeCompressed()
+		sigScript, err := txscript.NewScriptBuilder().AddData(sig).AddData(pkData).Script()
+		if err != nil {
+			return fmt.Errorf("failed to build signature script for input %d: %v", i, err)
+		}
+		txIn.SignatureScript = sigScript
+		return nil
+	}
+}
+
+// signWitnessInput fills in the witness for a P2WPKH-style input (native or
+// P2SH-wrapped), given the witness scriptCode to sign over.
+func signWitnessInput(tx *wire.MsgTx, txIn *wire.TxIn, hashes *txscript.TxSigHashes, i int, utxo UTXO, scriptCode []byte, signer Signer) error {
+	hash, err := txscript.CalcWitnessSigHash(scriptCode, hashes, txscript.SigHashAll, tx, i, utxo.Value)
+	if err != nil {
+		return fmt.Errorf("failed to calculate segwit signature hash for input %d: %v", i, err)
+	}
+	rawSig, err := signer.Sign(hash, txscript.SigHashAll)
+	if err != nil {
+		return fmt.Errorf("failed to sign input %d: %v", i, err)
+	}
+	sig := append(rawSig.Serialize(), byte(txscript.SigHashAll))
+
+	txIn.Witness = wire.TxWitness{
+		sig,
+		signer.PublicKey().SerializeCompressed(),
+	}
+	return nil
+}